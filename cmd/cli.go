@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+type command struct {
+	name    string
+	args    []string // positional arguments, e.g. "script"
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{"run", []string{"script"}, "Run a lox script", runScript},
+	{"repl", nil, "Start an interactive shell", runPrompt},
+	{"tokens", []string{"script"}, "Print the tokens of a lox script", runTokens},
+	{"check", []string{"script"}, "Report errors in a lox script without running it", runCheck},
+}
+
+// Main parses command line arguments (without the program name), runs the
+// selected subcommand and returns the process exit code. With no subcommand
+// it starts the shell, and "glox script.lox" is short for "glox run script.lox".
+func Main(args []string) int {
+	global := flag.NewFlagSet("glox", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	globalFlags(global)
+	global.Usage = func() {
+		usage(global.Output())
+		fmt.Fprintf(global.Output(), "\nFlags:\n")
+		global.PrintDefaults()
+	}
+	if err := global.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if !validFlags() {
+		return ExitUsage
+	}
+
+	args = global.Args()
+	if len(args) == 0 {
+		return runPrompt(nil)
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.execute(args[1:])
+		}
+	}
+	if len(args) == 1 {
+		return commands[0].execute(args)
+	}
+	fmt.Fprintf(stderr, "glox: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return ExitUsage
+}
+
+// globalFlags registers the flags accepted both before and after the
+// subcommand. The current values are the defaults so that parsing the
+// subcommand flags keeps what was set before it.
+func globalFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&noColor, "no-color", noColor, "Disable colored error output")
+	fs.StringVar(&diagnosticsFormat, "diagnostics", diagnosticsFormat, "Error output `format` on stderr: text or json")
+}
+
+func validFlags() bool {
+	if diagnosticsFormat != "text" && diagnosticsFormat != "json" {
+		fmt.Fprintf(stderr, "glox: invalid --diagnostics format %q, want text or json\n", diagnosticsFormat)
+		return false
+	}
+	return true
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: glox [flags] <command> [arguments]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun \"glox <command> --help\" for more information about a command.\n")
+}
+
+func (c command) execute(args []string) int {
+	fs := flag.NewFlagSet("glox "+c.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	globalFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: glox %s", c.name)
+		for _, a := range c.args {
+			fmt.Fprintf(fs.Output(), " <%s>", a)
+		}
+		fmt.Fprintf(fs.Output(), "\n\n%s.\n\nFlags:\n", c.summary)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if !validFlags() {
+		return ExitUsage
+	}
+	if fs.NArg() != len(c.args) {
+		fs.Usage()
+		return ExitUsage
+	}
+	return c.run(fs.Args())
+}
+
+func runScript(args []string) int {
+	return Script(args[0])
+}
+
+func runPrompt(args []string) int {
+	Prompt()
+	return ExitOK
+}
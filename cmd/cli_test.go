@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMainDispatch(t *testing.T) {
+	_, errOut, restore := captureOutput()
+	defer restore()
+
+	ok := filepath.Join(t.TempDir(), "ok.lox")
+	if err := ioutil.WriteFile(ok, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{[]string{"--help"}, ExitOK, "Usage: glox [flags] <command> [arguments]"},
+		{[]string{"check", "--help"}, ExitOK, "Usage: glox check <script>"},
+		{[]string{"--bogus"}, ExitUsage, "flag provided but not defined: -bogus"},
+		{[]string{"check"}, ExitUsage, "Usage: glox check <script>"},
+		{[]string{"check", ok, ok}, ExitUsage, "Usage: glox check <script>"},
+		{[]string{"repl", ok}, ExitUsage, "Usage: glox repl"},
+		{[]string{"chek", ok}, ExitUsage, "glox: unknown command \"chek\""},
+		{[]string{ok}, ExitOK, ""},
+		{[]string{"run", ok}, ExitOK, ""},
+		{[]string{"check", ok}, ExitOK, ""},
+		{[]string{"tokens", ok}, ExitOK, ""},
+	}
+	for _, testCase := range testCases {
+		errOut.Reset()
+		diagnosticsFormat = "text"
+		if code := Main(testCase.args); code != testCase.code {
+			t.Errorf("%v: Exit code expected: %v, Got: %v", testCase.args, testCase.code, code)
+		}
+		if !strings.Contains(errOut.String(), testCase.stderr) {
+			t.Errorf("%v: Errors expected to contain: %q, Got: %q", testCase.args, testCase.stderr, errOut.String())
+		}
+		if testCase.stderr == "" && errOut.Len() != 0 {
+			t.Errorf("%v: Errors expected: none, Got: %q", testCase.args, errOut.String())
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package cmd
+
+// Exit codes returned to the shell, following the BSD sysexits.h convention.
+const (
+	ExitOK           = 0
+	ExitUsage        = 64 // command line usage error
+	ExitCompileError = 65 // source could not be tokenized or parsed
+	ExitRuntimeError = 70 // error while executing the script
+	ExitIOError      = 74 // script could not be read
+)
@@ -0,0 +1,352 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupt is returned by ReadLine when the user presses Ctrl-C
+var ErrInterrupt = errors.New("Interrupted")
+
+// Editor reads lines from a terminal with Emacs style editing keys, history
+// and tab completion. When input is not a terminal it reads plain lines.
+type Editor struct {
+	// Complete returns the candidates for the word left of the cursor
+	Complete func(word string) []string
+
+	in          *bufio.Reader
+	out         io.Writer
+	fd          int
+	history     []string
+	historyFile string
+	raw         bool // last ReadLine used the terminal line editor
+}
+
+// NewEditor creates new instance of editor reading from stdin
+func NewEditor() *Editor {
+	return &Editor{
+		in:  bufio.NewReader(os.Stdin),
+		out: os.Stdout,
+		fd:  int(os.Stdin.Fd()),
+	}
+}
+
+// ReadLine shows prompt and returns the next line without its newline.
+// It returns io.EOF on Ctrl-D at an empty line and ErrInterrupt on Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	e.raw = err == nil
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+		text, err := e.in.ReadString('\n')
+		if err == io.EOF && text != "" {
+			err = nil
+		}
+		return strings.TrimRight(text, "\r\n"), err
+	}
+	defer restore()
+	return e.edit(prompt)
+}
+
+// Interactive reports whether the last ReadLine read from a terminal with
+// line editing, rather than plain lines from a pipe or file
+func (e *Editor) Interactive() bool {
+	return e.raw
+}
+
+// Control keys
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// Keys decoded from escape sequences, outside the unicode range
+const (
+	keyUp = unicode.MaxRune + 1 + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+type state struct {
+	e       *Editor
+	prompt  string
+	buf     []rune
+	pos     int
+	histIdx int
+	saved   []rune // line being edited before moving through history
+}
+
+func (e *Editor) edit(prompt string) (string, error) {
+	s := &state{e: e, prompt: prompt, histIdx: len(e.history)}
+	s.refresh()
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+		if key == keyCtrlR {
+			if key, err = s.search(); err != nil {
+				return "", err
+			}
+		}
+
+		switch key {
+		case keyCR, keyLF:
+			fmt.Fprint(e.out, "\r\n")
+			return string(s.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupt
+		case keyCtrlD:
+			if len(s.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			s.delete(s.pos)
+		case keyBackspace, keyCtrlH:
+			if s.pos > 0 {
+				s.pos--
+				s.delete(s.pos)
+			}
+		case keyDelete:
+			s.delete(s.pos)
+		case keyLeft, keyCtrlB:
+			if s.pos > 0 {
+				s.pos--
+			}
+		case keyRight, keyCtrlF:
+			if s.pos < len(s.buf) {
+				s.pos++
+			}
+		case keyHome, keyCtrlA:
+			s.pos = 0
+		case keyEnd, keyCtrlE:
+			s.pos = len(s.buf)
+		case keyCtrlK:
+			s.buf = s.buf[:s.pos]
+		case keyCtrlU:
+			s.buf = append([]rune{}, s.buf[s.pos:]...)
+			s.pos = 0
+		case keyCtrlW:
+			start := s.pos
+			for start > 0 && s.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && s.buf[start-1] != ' ' {
+				start--
+			}
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+		case keyUp, keyCtrlP:
+			s.moveHistory(-1)
+		case keyDown, keyCtrlN:
+			s.moveHistory(1)
+		case keyTab:
+			s.complete()
+		default:
+			if unicode.IsPrint(key) {
+				s.insert(key)
+			}
+		}
+		s.refresh()
+	}
+}
+
+// readKey returns the next key press, decoding escape sequences
+func (e *Editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEscape {
+		return r, err
+	}
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r != '[' && r != 'O' {
+		return keyUnknown, nil
+	}
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	switch r {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	}
+	if r < '0' || r > '9' {
+		return keyUnknown, nil
+	}
+	// Sequences of the form ESC [ <number> ~
+	num := r
+	for r != '~' {
+		if r, _, err = e.in.ReadRune(); err != nil {
+			return 0, err
+		}
+	}
+	switch num {
+	case '1', '7':
+		return keyHome, nil
+	case '3':
+		return keyDelete, nil
+	case '4', '8':
+		return keyEnd, nil
+	}
+	return keyUnknown, nil
+}
+
+func (s *state) refresh() {
+	fmt.Fprintf(s.e.out, "\r%s%s\x1b[K", s.prompt, string(s.buf))
+	if n := len(s.buf) - s.pos; n > 0 {
+		fmt.Fprintf(s.e.out, "\x1b[%dD", n)
+	}
+}
+
+func (s *state) insert(r ...rune) {
+	s.buf = append(s.buf[:s.pos], append(r, s.buf[s.pos:]...)...)
+	s.pos += len(r)
+}
+
+func (s *state) delete(at int) {
+	if at < len(s.buf) {
+		s.buf = append(s.buf[:at], s.buf[at+1:]...)
+	}
+}
+
+func (s *state) moveHistory(dir int) {
+	idx := s.histIdx + dir
+	if idx < 0 || idx > len(s.e.history) {
+		return
+	}
+	if s.histIdx == len(s.e.history) {
+		s.saved = s.buf
+	}
+	s.histIdx = idx
+	if idx == len(s.e.history) {
+		s.buf = s.saved
+	} else {
+		s.buf = []rune(s.e.history[idx])
+	}
+	s.pos = len(s.buf)
+}
+
+// complete extends the word left of the cursor with the longest prefix
+// shared by all candidates, listing them when there is more than one.
+func (s *state) complete() {
+	start := s.pos
+	for start > 0 && (unicode.IsLetter(s.buf[start-1]) || unicode.IsDigit(s.buf[start-1]) || s.buf[start-1] == '_') {
+		start--
+	}
+	word := string(s.buf[start:s.pos])
+	var candidates []string
+	if s.e.Complete != nil && word != "" {
+		candidates = s.e.Complete(word)
+	}
+	if len(candidates) == 0 {
+		fmt.Fprint(s.e.out, "\a")
+		return
+	}
+
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) > len(word) {
+		s.insert([]rune(prefix[len(word):])...)
+		return
+	}
+	if len(candidates) > 1 {
+		fmt.Fprintf(s.e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
+
+// search runs an incremental reverse search through history (Ctrl-R).
+// It returns the key that ended the search, with the match copied into
+// the line unless the search was cancelled.
+func (s *state) search() (rune, error) {
+	var query []rune
+	idx := len(s.e.history)
+	match, failing := "", ""
+	find := func(from int) {
+		if from >= len(s.e.history) {
+			from = len(s.e.history) - 1
+		}
+		for i := from; i >= 0; i-- {
+			if strings.Contains(s.e.history[i], string(query)) {
+				idx, match, failing = i, s.e.history[i], ""
+				return
+			}
+		}
+		failing = "failing "
+	}
+
+	for {
+		fmt.Fprintf(s.e.out, "\r(%sreverse-i-search)`%s': %s\x1b[K", failing, string(query), match)
+		key, err := s.e.readKey()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case key == keyCtrlR:
+			find(idx - 1)
+		case key == keyBackspace || key == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				match = ""
+				find(len(s.e.history) - 1)
+			}
+		case key == keyCtrlG || key == keyCtrlC:
+			return keyUnknown, nil
+		case unicode.IsPrint(key):
+			query = append(query, key)
+			if !strings.Contains(match, string(query)) {
+				find(idx)
+			}
+		default:
+			if match != "" {
+				s.buf = []rune(match)
+				s.pos = len(s.buf)
+				s.histIdx = idx
+			}
+			return key, nil
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package lineedit
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string, history ...string) *Editor {
+	return &Editor{
+		in:      bufio.NewReader(strings.NewReader(input)),
+		out:     &bytes.Buffer{},
+		history: history,
+		Complete: func(word string) []string {
+			var matches []string
+			for _, k := range []string{"class", "false", "for", "fun", "print"} {
+				if strings.HasPrefix(k, word) {
+					matches = append(matches, k)
+				}
+			}
+			return matches
+		},
+	}
+}
+
+func TestEditKeys(t *testing.T) {
+	testCases := []struct {
+		description string
+		input       string
+		history     []string
+		result      string
+		err         error
+	}{
+		{"Plain line", "print 1;\r", nil, "print 1;", nil},
+		{"Backspace", "prinx\x7ft 1;\r", nil, "print 1;", nil},
+		{"Arrow left and insert", "prnt\x1b[D\x1b[Di\r", nil, "print", nil},
+		{"Home and end", "rint\x01p\x05;\r", nil, "print;", nil},
+		{"Delete key", "pxrint\x01\x1b[C\x1b[3~\r", nil, "print", nil},
+		{"Kill to end", "print 1;\x01\x1b[C\x1b[C\x0b\r", nil, "pr", nil},
+		{"Delete word", "print abc\x17\r", nil, "print ", nil},
+		{"History up", "\x1b[A\x1b[A\r", []string{"one", "two"}, "one", nil},
+		{"History up and down", "new\x1b[A\x1b[B\r", []string{"one"}, "new", nil},
+		{"Reverse search", "\x12ne\r", []string{"one", "two", "three"}, "one", nil},
+		{"Reverse search again", "\x12t\x12\r", []string{"two", "three"}, "two", nil},
+		{"Reverse search then edit", "\x12tw\x05!\r", []string{"two", "three"}, "two!", nil},
+		{"Reverse search cancelled", "x\x12tw\x07\r", []string{"two"}, "x", nil},
+		{"Complete unique", "pr\t 1;\r", nil, "print 1;", nil},
+		{"Complete common prefix", "f\t\r", nil, "f", nil},
+		{"Complete shared prefix", "fu\t\r", nil, "fun", nil},
+		{"Ctrl-D at empty line", "\x04", nil, "", io.EOF},
+		{"Ctrl-D deletes", "xprint\x01\x04\r", nil, "print", nil},
+		{"Ctrl-C", "print\x03", nil, "", ErrInterrupt},
+	}
+	for _, testCase := range testCases {
+		e := newTestEditor(testCase.input, testCase.history...)
+		result, err := e.edit("> ")
+		if result != testCase.result || err != testCase.err {
+			t.Errorf("%v: Expected: %q, %v, Got: %q, %v", testCase.description, testCase.result, testCase.err, result, err)
+		}
+	}
+}
+
+func TestReadLineWithoutTerminal(t *testing.T) {
+	e := newTestEditor("print 1;\nprint 2;")
+	e.fd = -1
+	for _, expected := range []string{"print 1;", "print 2;"} {
+		line, err := e.ReadLine("> ")
+		if line != expected || err != nil {
+			t.Errorf("Expected: %q, nil, Got: %q, %v", expected, line, err)
+		}
+		if e.Interactive() {
+			t.Errorf("Interactive expected: false, Got: true")
+		}
+	}
+	if _, err := e.ReadLine("> "); err != io.EOF {
+		t.Errorf("Expected: EOF, Got: %v", err)
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	e := newTestEditor("")
+	if err := e.LoadHistory(path); err != nil {
+		t.Fatalf("Error expected: nil, Got: %v", err)
+	}
+	for _, line := range []string{"one", "", "  ", "two", "two", "  three\n"} {
+		if err := e.AddHistory(line); err != nil {
+			t.Fatalf("Error expected: nil, Got: %v", err)
+		}
+	}
+	data, _ := ioutil.ReadFile(path)
+	if string(data) != "one\ntwo\n  three\n" {
+		t.Errorf("History file expected: %q, Got: %q", "one\ntwo\n  three\n", data)
+	}
+
+	e = newTestEditor("")
+	if err := e.LoadHistory(path); err != nil {
+		t.Fatalf("Error expected: nil, Got: %v", err)
+	}
+	if !reflect.DeepEqual(e.history, []string{"one", "two", "  three"}) {
+		t.Errorf("History expected: [one two   three], Got: %q", e.history)
+	}
+}
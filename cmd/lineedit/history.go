@@ -0,0 +1,66 @@
+package lineedit
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// maxHistory is the number of entries kept in memory and in the history file
+const maxHistory = 1000
+
+// LoadHistory reads earlier entries from path, one per line, and makes
+// AddHistory append new entries to it. A missing file is not an error.
+func (e *Editor) LoadHistory(path string) error {
+	e.historyFile = path
+	fd, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer fd.Close()
+
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+		data := strings.Join(e.history, "\n") + "\n"
+		return ioutil.WriteFile(path, []byte(data), 0600)
+	}
+	return nil
+}
+
+// AddHistory records line so it can be recalled with the arrow keys and
+// Ctrl-R. Blank lines and repeats of the previous entry are skipped;
+// leading whitespace such as indentation is kept.
+func (e *Editor) AddHistory(line string) error {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return nil
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[1:]
+	}
+
+	if e.historyFile == "" {
+		return nil
+	}
+	fd, err := os.OpenFile(e.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	_, err = fd.WriteString(line + "\n")
+	return err
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package lineedit
+
+import (
+	"errors"
+)
+
+// makeRaw is only implemented on Linux. Elsewhere lines are read as typed.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("Raw mode not supported")
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/asatale/go-lox/interpreter"
+	"github.com/asatale/go-lox/interpreter/tokenizer"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// metaCommand is a shell command starting with ":", e.g. ":load file.lox"
+type metaCommand struct {
+	name    string
+	args    string
+	summary string
+	run     func(arg string, s *session)
+}
+
+var metaCommands []metaCommand
+
+func init() {
+	metaCommands = []metaCommand{
+		{"help", "", "List shell commands", metaHelp},
+		{"load", "<file>", "Run a lox file in the current session", metaLoad},
+		{"reset", "", "Clear the session state", metaReset},
+		{"tokens", "<source>", "Show how source is tokenized", metaTokens},
+		{"time", "<source>", "Run source and report how long it took", metaTime},
+	}
+}
+
+// runMeta executes line, which starts with ":", as a shell command
+func runMeta(line string, s *session) {
+	name, arg := line[1:], ""
+	if i := strings.IndexAny(name, " \t"); i >= 0 {
+		name, arg = name[:i], strings.TrimSpace(name[i+1:])
+	}
+	for _, m := range metaCommands {
+		if m.name == name {
+			if m.args != "" && arg == "" {
+				fmt.Fprintf(stderr, "Usage: :%s %s\n", m.name, m.args)
+				return
+			}
+			m.run(arg, s)
+			return
+		}
+	}
+	fmt.Fprintf(stderr, "Unknown command :%s, try :help\n", name)
+}
+
+func metaHelp(arg string, s *session) {
+	for _, m := range metaCommands {
+		fmt.Fprintf(stdout, "  :%-18s %s\n", strings.TrimSpace(m.name+" "+m.args), m.summary)
+	}
+}
+
+func metaLoad(filename string, s *session) {
+	source, err := ioutil.ReadFile(filename)
+	if err != nil {
+		reportError(filename, nil, err)
+		return
+	}
+	if err := s.run(bytes.NewReader(source)); err != nil && err != interpreter.EOFError {
+		reportError(filename, source, err)
+	}
+}
+
+func metaReset(arg string, s *session) {
+	s.reset()
+	fmt.Fprintln(stdout, "Session cleared")
+}
+
+func metaTokens(source string, s *session) {
+	tk := tokenizer.NewTokenizerWithTable(bytes.NewBufferString(source), s.names)
+	for {
+		token, err := tk.GetToken()
+		if err != nil {
+			reportError(stdinName, []byte(source), err)
+			return
+		}
+		if token.Type == tokenizer.EOF {
+			return
+		}
+		printToken(token)
+	}
+}
+
+func metaTime(source string, s *session) {
+	start := time.Now()
+	err := s.run(bytes.NewBufferString(source))
+	elapsed := time.Since(start)
+	if err != nil && err != interpreter.EOFError {
+		reportError(stdinName, []byte(source), err)
+	}
+	fmt.Fprintf(stdout, "Elapsed: %v\n", elapsed)
+}
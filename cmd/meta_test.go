@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureOutput redirects stdout and stderr to buffers until the returned
+// function is called
+func captureOutput() (out, errOut *bytes.Buffer, restore func()) {
+	out, errOut = &bytes.Buffer{}, &bytes.Buffer{}
+	stdout, stderr, noColor = out, errOut, true
+	return out, errOut, func() { stdout, stderr, noColor = os.Stdout, os.Stderr, false }
+}
+
+func TestRunMeta(t *testing.T) {
+	out, errOut, restore := captureOutput()
+	defer restore()
+
+	missing := filepath.Join(t.TempDir(), "missing.lox")
+	testCases := []struct {
+		line   string
+		stdout string
+		stderr string
+	}{
+		{":help", "  :reset              Clear the session state\n", ""},
+		{":load", "", "Usage: :load <file>\n"},
+		{":tokens", "", "Usage: :tokens <source>\n"},
+		{":nope", "", "Unknown command :nope, try :help\n"},
+		{":tokens  print  x;", "1:1\tprint\t\"print\"\n1:8\tidentifier\t\"x\"\n1:9\t;\t\";\"\n", ""},
+		{":tokens x = \"open", "1:1\tidentifier\t\"x\"\n1:3\t=\t\"=\"\n", "error: Unterminated \"\n"},
+		{":load " + missing, "", "error: open " + missing + ": no such file or directory\n"},
+	}
+	for _, testCase := range testCases {
+		out.Reset()
+		errOut.Reset()
+		runMeta(testCase.line, newSession(nil))
+		if !strings.Contains(out.String(), testCase.stdout) {
+			t.Errorf("%q: Output expected to contain: %q, Got: %q", testCase.line, testCase.stdout, out.String())
+		}
+		if testCase.stdout == "" && out.Len() != 0 {
+			t.Errorf("%q: Output expected: none, Got: %q", testCase.line, out.String())
+		}
+		if !strings.HasPrefix(errOut.String(), testCase.stderr) {
+			t.Errorf("%q: Errors expected to start with: %q, Got: %q", testCase.line, testCase.stderr, errOut.String())
+		}
+		if testCase.stderr == "" && errOut.Len() != 0 {
+			t.Errorf("%q: Errors expected: none, Got: %q", testCase.line, errOut.String())
+		}
+	}
+}
+
+func TestMetaReset(t *testing.T) {
+	out, _, restore := captureOutput()
+	defer restore()
+
+	s := newSession(nil)
+	runMeta(":tokens count \"count\" other", s)
+	s.input.WriteString("fun f() {\n")
+	if len(s.names) != 2 {
+		t.Fatalf("Names expected: 2, Got: %v", s.names)
+	}
+
+	out.Reset()
+	runMeta(":reset", s)
+	if len(s.names) != 0 || s.input.Len() != 0 {
+		t.Errorf("Session expected to be empty, Got names: %v, input: %q", s.names, s.input.String())
+	}
+	if out.String() != "Session cleared\n" {
+		t.Errorf("Output expected: %q, Got: %q", "Session cleared\n", out.String())
+	}
+}
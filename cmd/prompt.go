@@ -1,59 +1,192 @@
 package cmd
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"fmt"
+	"github.com/asatale/go-lox/cmd/lineedit"
 	"github.com/asatale/go-lox/interpreter"
+	"github.com/asatale/go-lox/interpreter/tokenizer"
 	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 )
 
+const (
+	primaryPrompt      = "Glox Shell>>> "
+	continuationPrompt = "... "
+	historyFile        = ".glox_history"
+	stdinName          = "<stdin>"
+	exitHint           = "(To exit, press Ctrl-C again or Ctrl-D)"
+	// maxSessionNames bounds the intern table of a session, which is
+	// cleared once it grows past this many entries
+	maxSessionNames = 4096
+)
+
+type readResult struct {
+	text string
+	err  error
+}
+
+// session is the state shared by everything run in one shell session
+type session struct {
+	sigs  <-chan os.Signal      // interrupts cancelling the running input
+	names tokenizer.InternTable // recent identifiers and strings, see maxSessionNames
+	input bytes.Buffer          // lines collected until they form a complete statement
+}
+
+func newSession(sigs <-chan os.Signal) *session {
+	return &session{sigs: sigs, names: tokenizer.InternTable{}}
+}
+
+// reset drops everything the session has collected
+func (s *session) reset() {
+	s.names = tokenizer.InternTable{}
+	s.input.Reset()
+}
+
 func Prompt() {
 
+	editor := lineedit.NewEditor()
+	editor.Complete = completeKeyword
+	if home, err := os.UserHomeDir(); err == nil {
+		if err := editor.LoadHistory(filepath.Join(home, historyFile)); err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading history:", err)
+		}
+	}
+
 	sigs := make(chan os.Signal, 1)
-	data := make(chan string, 1)
-	control := make(chan struct{}, 1)
-	control <- struct{}{}
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+	s := newSession(sigs)
+	data := make(chan readResult, 1)
+	control := make(chan string, 1)
+	control <- primaryPrompt
 
 	go func() {
 	Loop1:
 		for {
 			select {
-			case _, ok := <-control:
+			case prompt, ok := <-control:
 				if !ok {
 					break Loop1
 				}
-				fmt.Printf("Glox Shell>>> ")
-				reader := bufio.NewReader(os.Stdin)
-				text, err := reader.ReadString('\n')
-				if err == io.EOF {
+				text, err := editor.ReadLine(prompt)
+				if err != nil && err != lineedit.ErrInterrupt {
 					close(data)
 					break Loop1
 				}
-				data <- text
+				data <- readResult{text: text, err: err}
 			}
 		}
 	}()
 
+	// interrupted is set after Ctrl-C at the prompt; a second one exits
+	interrupted := false
 Loop2:
 	for {
 		select {
 		case <-sigs:
-			fmt.Println("Got shutdown, exiting")
-			break Loop2
-		case s, ok := <-data:
-			if !ok {
+			// Ctrl-C while reading without a terminal line editor
+			if interrupted {
+				fmt.Println()
 				break Loop2
 			}
-			source := bytes.NewBufferString(s)
-			err := interpreter.Run(source)
-			if err != nil && err != interpreter.EOFError {
-				fmt.Println("Error in interpreter: ", err)
+			interrupted = true
+			s.input.Reset()
+			fmt.Printf("\n%s\n%s", exitHint, primaryPrompt)
+		case r, ok := <-data:
+			if !ok {
+				// EOF in the middle of a statement still runs it so that
+				// its error is reported
+				if s.input.Len() > 0 {
+					s.runInput()
+				}
 				break Loop2
 			}
-			control <- struct{}{}
+			if r.err == lineedit.ErrInterrupt {
+				if interrupted {
+					break Loop2
+				}
+				interrupted = true
+				s.input.Reset()
+				fmt.Println(exitHint)
+				control <- primaryPrompt
+				continue
+			}
+			interrupted = false
+			// Piped input is a script, not something to recall later
+			if editor.Interactive() {
+				if err := editor.AddHistory(r.text); err != nil {
+					fmt.Fprintln(os.Stderr, "Error saving history:", err)
+				}
+			}
+			if s.input.Len() == 0 && strings.HasPrefix(strings.TrimSpace(r.text), ":") {
+				runMeta(strings.TrimSpace(r.text), s)
+				control <- primaryPrompt
+				continue
+			}
+			s.input.WriteString(r.text + "\n")
+			if !tokenizer.IsComplete(bytes.NewReader(s.input.Bytes())) {
+				control <- continuationPrompt
+				continue
+			}
+			s.runInput()
+			control <- primaryPrompt
 		}
 	}
 	close(control)
 }
+
+// runInput runs the statement collected in s.input, reports its error and
+// resets s.input
+func (s *session) runInput() {
+	source := append([]byte(nil), s.input.Bytes()...)
+	err := s.run(&s.input)
+	if err != nil && err != interpreter.EOFError {
+		reportError(stdinName, source, err)
+	}
+	s.input.Reset()
+}
+
+// run executes source, cancelling it when an interrupt arrives on s.sigs
+func (s *session) run(source io.Reader) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-s.sigs:
+			cancel()
+		case <-done:
+		}
+	}()
+	err := interpreter.RunContextWithTable(ctx, source, s.names)
+	if len(s.names) > maxSessionNames {
+		s.names = tokenizer.InternTable{}
+	}
+	close(done)
+	<-stopped
+	// Drop an interrupt that arrived as the run finished, otherwise the
+	// prompt takes it as the first Ctrl-C and the next one exits
+	select {
+	case <-s.sigs:
+	default:
+	}
+	return err
+}
+
+// completeKeyword returns the keywords starting with word
+func completeKeyword(word string) []string {
+	var matches []string
+	for _, k := range tokenizer.Keywords() {
+		if strings.HasPrefix(k, word) {
+			matches = append(matches, k)
+		}
+	}
+	return matches
+}
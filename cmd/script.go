@@ -1,21 +1,70 @@
 package cmd
 
 import (
-	"fmt"
+	"bytes"
+	"errors"
 	"github.com/asatale/go-lox/interpreter"
+	"github.com/asatale/go-lox/interpreter/diagnostics"
+	"github.com/asatale/go-lox/interpreter/tokenizer"
+	"io"
+	"io/ioutil"
 	"os"
 )
 
-func Script(filename string) {
-	fd, err := os.Open(filename)
+// noColor disables ANSI colors in error output
+var noColor bool
+
+// diagnosticsFormat selects how errors are reported: "text" or "json"
+var diagnosticsFormat = "text"
+
+// stdout and stderr receive command output and error reports, replaced in
+// tests
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// Script runs the lox file at filename and returns the process exit code.
+// Errors are reported on stderr with the offending source line.
+func Script(filename string) int {
+	source, err := ioutil.ReadFile(filename)
 	if err != nil {
-		panic(err)
+		return reportError(filename, nil, err)
 	}
-	defer fd.Close()
 
-	err = interpreter.Run(fd)
-	if err != nil {
-		fmt.Println("Error in interpreter: ", err)
-		return
+	err = interpreter.Run(bytes.NewReader(source))
+	if err == nil || err == interpreter.EOFError {
+		reportSuccess()
+		return ExitOK
+	}
+	return reportError(filename, source, err)
+}
+
+// reportError prints err for filename on stderr and returns the exit code
+// matching its kind. source is the text of filename, if available.
+func reportError(filename string, source []byte, err error) int {
+	d := diagnostics.FromError(filename, err)
+	if diagnosticsFormat == "json" {
+		diagnostics.WriteJSON(stderr, []diagnostics.Diagnostic{d})
+	} else {
+		d.Render(stderr, source, !noColor && diagnostics.UseColor(os.Stderr))
+	}
+
+	var tkErr *tokenizer.TokenError
+	var pathErr *os.PathError
+	switch {
+	case errors.As(err, &tkErr):
+		return ExitCompileError
+	case errors.As(err, &pathErr):
+		return ExitIOError
+	}
+	return ExitRuntimeError
+}
+
+// reportSuccess prints an empty list of diagnostics in JSON mode, so that
+// tools always find an array on stderr
+func reportSuccess() {
+	if diagnosticsFormat == "json" {
+		diagnostics.WriteJSON(stderr, nil)
 	}
 }
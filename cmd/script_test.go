@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScriptMissingFile(t *testing.T) {
+	var out bytes.Buffer
+	stderr = &out
+	defer func() { stderr, diagnosticsFormat, noColor = os.Stderr, "text", false }()
+
+	missing := filepath.Join(t.TempDir(), "missing.lox")
+	testCases := []struct {
+		description string
+		args        []string
+	}{
+		{"run", []string{"--diagnostics=json", missing}},
+		{"check", []string{"--diagnostics=json", "check", missing}},
+		{"tokens", []string{"--diagnostics=json", "tokens", missing}},
+		{"flag after command", []string{"check", "--diagnostics=json", missing}},
+		{"flag after implicit run", []string{"--no-color", "--diagnostics", "json", missing}},
+	}
+	for _, testCase := range testCases {
+		out.Reset()
+		diagnosticsFormat = "text"
+		if code := Main(testCase.args); code != ExitIOError {
+			t.Errorf("%v: Exit code expected: %v, Got: %v", testCase.description, ExitIOError, code)
+		}
+		var result []struct {
+			Code string `json:"code"`
+			File string `json:"file"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("%v: Invalid JSON %q: %v", testCase.description, out.String(), err)
+		}
+		if len(result) != 1 || result[0].Code != "F001" || result[0].File != missing {
+			t.Errorf("%v: Unexpected diagnostics: %s", testCase.description, out.String())
+		}
+	}
+
+	out.Reset()
+	diagnosticsFormat, noColor = "text", true
+	if code := Script(missing); code != ExitIOError {
+		t.Errorf("Exit code expected: %v, Got: %v", ExitIOError, code)
+	}
+	if !strings.HasPrefix(out.String(), "error: open "+missing) {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+}
+
+func TestInvalidDiagnosticsFormat(t *testing.T) {
+	_, _, restore := captureOutput()
+	defer restore()
+	defer func() { diagnosticsFormat = "text" }()
+	for _, args := range [][]string{
+		{"--diagnostics=xml", "check", "a.lox"},
+		{"check", "--diagnostics=xml", "a.lox"},
+	} {
+		diagnosticsFormat = "text"
+		if code := Main(args); code != ExitUsage {
+			t.Errorf("%v: Exit code expected: %v, Got: %v", args, ExitUsage, code)
+		}
+	}
+}
+
+func TestScriptExitCodes(t *testing.T) {
+	_, errOut, restore := captureOutput()
+	defer restore()
+
+	dir := t.TempDir()
+	write := func(name, source string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(source), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	testCases := []struct {
+		description string
+		filename    string
+		code        int
+		stderr      string
+	}{
+		{"clean run", write("ok.lox", "print \"Hello\";\n"), ExitOK, ""},
+		{"tokenizer error", write("bad.lox", "var a = 1;\nvar b = 2abc;\n"), ExitCompileError,
+			"error: Invalid identifier \"2abc\"\n --> " + filepath.Join(dir, "bad.lox") + ":2:9\n"},
+		{"missing file", filepath.Join(dir, "missing.lox"), ExitIOError, "error: open "},
+	}
+	for _, testCase := range testCases {
+		errOut.Reset()
+		if code := Script(testCase.filename); code != testCase.code {
+			t.Errorf("%v: Exit code expected: %v, Got: %v", testCase.description, testCase.code, code)
+		}
+		if !strings.HasPrefix(errOut.String(), testCase.stderr) {
+			t.Errorf("%v: Errors expected to start with: %q, Got: %q", testCase.description, testCase.stderr, errOut.String())
+		}
+		if testCase.stderr == "" && errOut.Len() != 0 {
+			t.Errorf("%v: Errors expected: none, Got: %q", testCase.description, errOut.String())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/asatale/go-lox/interpreter/tokenizer"
+	"io/ioutil"
+)
+
+func runTokens(args []string) int {
+	return scanFile(args[0], printToken)
+}
+
+func printToken(token tokenizer.Token) {
+	fmt.Fprintf(stdout, "%d:%d\t%v\t%q\n", token.Line, token.Column, token.Type, token.Value)
+}
+
+func runCheck(args []string) int {
+	return scanFile(args[0], func(tokenizer.Token) {})
+}
+
+// scanFile tokenizes filename, calling fn for every token up to EOF, and
+// returns the exit code for the first error encountered.
+func scanFile(filename string, fn func(tokenizer.Token)) int {
+	source, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return reportError(filename, nil, err)
+	}
+
+	tk := tokenizer.NewTokenizer(bytes.NewReader(source))
+	for {
+		token, err := tk.GetToken()
+		if err != nil {
+			return reportError(filename, source, err)
+		}
+		if token.Type == tokenizer.EOF {
+			reportSuccess()
+			return ExitOK
+		}
+		fn(token)
+	}
+}
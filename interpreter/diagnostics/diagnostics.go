@@ -0,0 +1,223 @@
+package diagnostics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/asatale/go-lox/interpreter/tokenizer"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	}
+	return "Unknown Severity"
+}
+
+// Codes for errors that do not carry one of their own
+const (
+	CodeInternal = "E001"
+	CodeFile     = "F001" // source file could not be read
+)
+
+// coder is implemented by errors that carry a stable code
+type coder interface {
+	Code() string
+}
+
+// Span is a range of source text on a single line. Line and Column are
+// 1-based; a zero Line means there is no source position. Column and
+// Length count characters (Unicode code points), not bytes.
+type Span struct {
+	File   string
+	Line   int
+	Column int
+	Length int // characters to underline, at least one caret is drawn
+}
+
+// Related points at another place in the source relevant to a diagnostic
+type Related struct {
+	Span
+	Message string
+}
+
+// Diagnostic describes a problem in a source file
+type Diagnostic struct {
+	Span
+	Severity Severity
+	Code     string
+	Message  string
+	Notes    []string
+	Help     string
+	Related  []Related
+}
+
+// FromError builds a diagnostic for err raised while running file
+func FromError(file string, err error) Diagnostic {
+	d := Diagnostic{
+		Span:     Span{File: file},
+		Severity: Error,
+		Code:     CodeInternal,
+		Message:  err.Error(),
+	}
+	var c coder
+	if errors.As(err, &c) {
+		d.Code = c.Code()
+	}
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		d.Code = CodeFile
+	}
+	var tkErr *tokenizer.TokenError
+	if errors.As(err, &tkErr) {
+		d.Code = tkErr.Code
+		d.Message = tkErr.Msg
+		d.Line = tkErr.Line
+		d.Column = tkErr.Column
+		d.Length = tkErr.Length
+		if tkErr.Incomplete {
+			d.Notes = append(d.Notes, "the source ended before this was closed")
+		}
+	}
+	return d
+}
+
+// ANSI escape sequences used when color is enabled
+const (
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	red    = "\x1b[1;31m"
+	yellow = "\x1b[1;33m"
+	blue   = "\x1b[1;34m"
+	cyan   = "\x1b[1;36m"
+)
+
+// Render writes d to w in the form
+//
+//	error: Invalid identifier "2abc"
+//	  --> script.lox:2:9
+//	   |
+//	 2 | var b = 2abc;
+//	   |         ^^^^
+//	   = note: ...
+//	   = help: ...
+//
+// source is the text of d.File and may be nil when it is not available.
+func (d Diagnostic) Render(w io.Writer, source []byte, color bool) {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + reset
+	}
+
+	label := red
+	if d.Severity == Warning {
+		label = yellow
+	}
+	fmt.Fprintf(w, "%s%s\n", paint(label, d.Severity.String()+":"), paint(bold, " "+d.Message))
+
+	if d.Line == 0 {
+		if d.File != "" {
+			fmt.Fprintf(w, "  %s %s\n", paint(blue, "-->"), d.File)
+		}
+		d.renderNotes(w, "  ", paint)
+		return
+	}
+
+	gutter := strings.Repeat(" ", len(fmt.Sprint(d.Line)))
+	fmt.Fprintf(w, "%s%s %s:%d:%d\n", gutter, paint(blue, "-->"), d.File, d.Line, d.Column)
+	if text, ok := sourceLine(source, d.Line); ok {
+		fmt.Fprintf(w, "%s %s\n", gutter, paint(blue, "|"))
+		fmt.Fprintf(w, "%s %s %s\n", paint(blue, fmt.Sprint(d.Line)), paint(blue, "|"), text)
+		fmt.Fprintf(w, "%s %s %s%s\n", gutter, paint(blue, "|"), padding(text, d.Column), paint(label, underline(text, d.Column, d.Length)))
+	}
+	d.renderNotes(w, gutter+" ", paint)
+}
+
+func (d Diagnostic) renderNotes(w io.Writer, indent string, paint func(code, s string) string) {
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s%s %s\n", indent, paint(blue, "="), paint(bold, "note:")+" "+note)
+	}
+	for _, r := range d.Related {
+		fmt.Fprintf(w, "%s%s %s %s at %s:%d:%d\n", indent, paint(blue, "="), paint(bold, "note:"), r.Message, r.File, r.Line, r.Column)
+	}
+	if d.Help != "" {
+		fmt.Fprintf(w, "%s%s %s\n", indent, paint(blue, "="), paint(cyan, "help:")+" "+d.Help)
+	}
+}
+
+// sourceLine returns the 1-based line n of source without its newline
+func sourceLine(source []byte, n int) (string, bool) {
+	if source == nil {
+		return "", false
+	}
+	lines := bytes.Split(source, []byte("\n"))
+	if n < 1 || n > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(string(lines[n-1]), "\r"), true
+}
+
+// padding returns the blank space that lines up with character column col
+// of text, keeping tabs so that the caret stays aligned
+func padding(text string, col int) string {
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if i >= col-1 {
+			break
+		}
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
+
+// underline returns carets for length characters of text starting at
+// col, stopping at the end of the line
+func underline(text string, col int, length int) string {
+	size := utf8.RuneCountInString(text)
+	start := col - 1
+	if start > size {
+		start = size
+	}
+	end := start + length
+	if end > size {
+		end = size
+	}
+	n := end - start
+	if n < 1 {
+		n = 1
+	}
+	return strings.Repeat("^", n)
+}
+
+// UseColor reports whether output to f should be colored: f must be a
+// terminal and the NO_COLOR environment variable must not be set
+func UseColor(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
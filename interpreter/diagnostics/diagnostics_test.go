@@ -0,0 +1,168 @@
+package diagnostics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/asatale/go-lox/interpreter/tokenizer"
+	"os"
+	"testing"
+)
+
+func scanError(source string) error {
+	tk := tokenizer.NewTokenizer(bytes.NewBufferString(source))
+	for {
+		token, err := tk.GetToken()
+		if err != nil {
+			return err
+		}
+		if token.Type == tokenizer.EOF {
+			return nil
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		description string
+		source      string
+		diagnostic  Diagnostic
+		result      string
+	}{
+		{
+			description: "Invalid identifier",
+			source:      "var a = 1;\nvar b = 2abc;\n",
+			result: `error: Invalid identifier "2abc"
+ --> test.lox:2:9
+  |
+2 | var b = 2abc;
+  |         ^^^^
+`,
+		},
+		{
+			description: "Unterminated string",
+			source:      "print\t\"Hello",
+			result: `error: Unterminated "
+ --> test.lox:1:7
+  |
+1 | print	"Hello
+  |      	^
+  = note: the source ended before this was closed
+`,
+		},
+		{
+			description: "Columns count characters",
+			source:      "var s = \"héllo\"; x = 2abc;",
+			result: `error: Invalid identifier "2abc"
+ --> test.lox:1:22
+  |
+1 | var s = "héllo"; x = 2abc;
+  |                      ^^^^
+`,
+		},
+		{
+			description: "Error without position",
+			diagnostic: Diagnostic{
+				Span:     Span{File: "test.lox"},
+				Severity: Warning,
+				Message:  "Something odd",
+				Help:     "try again",
+			},
+			result: `warning: Something odd
+  --> test.lox
+  = help: try again
+`,
+		},
+		{
+			description: "Source not available",
+			diagnostic: Diagnostic{
+				Span:    Span{File: "test.lox", Line: 12, Column: 3},
+				Message: "Bad thing",
+				Notes:   []string{"first", "second"},
+				Related: []Related{{Span{File: "test.lox", Line: 4, Column: 1}, "started here"}},
+			},
+			result: `error: Bad thing
+  --> test.lox:12:3
+   = note: first
+   = note: second
+   = note: started here at test.lox:4:1
+`,
+		},
+	}
+	for _, testCase := range testCases {
+		d := testCase.diagnostic
+		if testCase.source != "" {
+			d = FromError("test.lox", scanError(testCase.source))
+		}
+		var out bytes.Buffer
+		d.Render(&out, []byte(testCase.source), false)
+		if out.String() != testCase.result {
+			t.Errorf("%v: Expected:\n%s\nGot:\n%s", testCase.description, testCase.result, out.String())
+		}
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	var out bytes.Buffer
+	FromError("test.lox", errors.New("Interrupted")).Render(&out, nil, true)
+	expected := "\x1b[1;31merror:\x1b[0m\x1b[1m Interrupted\x1b[0m\n  \x1b[1;34m-->\x1b[0m test.lox\n"
+	if out.String() != expected {
+		t.Errorf("Expected: %q, Got: %q", expected, out.String())
+	}
+}
+
+type codedError struct{}
+
+func (codedError) Error() string { return "Coded" }
+func (codedError) Code() string  { return "X042" }
+
+func TestFromErrorCode(t *testing.T) {
+	testCases := []struct {
+		err  error
+		code string
+	}{
+		{errors.New("plain"), CodeInternal},
+		{codedError{}, "X042"},
+		{fmt.Errorf("wrapped: %w", codedError{}), "X042"},
+		{scanError("x = 2abc;"), tokenizer.CodeInvalidIdentifier},
+		{&os.PathError{Op: "open", Path: "x.lox", Err: os.ErrNotExist}, CodeFile},
+	}
+	for _, testCase := range testCases {
+		if d := FromError("test.lox", testCase.err); d.Code != testCase.code {
+			t.Errorf("%v: Code expected: %v, Got: %v", testCase.err, testCase.code, d.Code)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	diagnostics := []Diagnostic{
+		FromError("test.lox", scanError("var b = 2abc;")),
+		FromError("test.lox", scanError("x;\n/* open")),
+		{
+			Span:     Span{File: "test.lox"},
+			Severity: Warning,
+			Code:     "R001",
+			Message:  "Interrupted",
+			Help:     "try again",
+			Related:  []Related{{Span{File: "other.lox", Line: 3, Column: 2, Length: 4}, "defined here"}},
+		},
+	}
+	var out bytes.Buffer
+	if err := WriteJSON(&out, diagnostics); err != nil {
+		t.Fatalf("Error expected: nil, Got: %v", err)
+	}
+	expected := `[` +
+		`{"severity":"error","code":"L004","message":"Invalid identifier \"2abc\"","file":"test.lox","startLine":1,"startColumn":9,"endLine":1,"endColumn":13,"notes":[],"related":[]},` +
+		`{"severity":"error","code":"L003","message":"Unterminated block comment","file":"test.lox","startLine":2,"startColumn":1,"endLine":2,"endColumn":3,"notes":["the source ended before this was closed"],"related":[]},` +
+		`{"severity":"warning","code":"R001","message":"Interrupted","file":"test.lox","startLine":0,"startColumn":0,"endLine":0,"endColumn":0,"notes":[],"help":"try again","related":[{"file":"other.lox","startLine":3,"startColumn":2,"endLine":3,"endColumn":6,"message":"defined here"}]}` +
+		"]\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	WriteJSON(&out, nil)
+	if out.String() != "[]\n" {
+		t.Errorf("Expected: %q, Got: %q", "[]\n", out.String())
+	}
+}
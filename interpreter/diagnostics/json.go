@@ -0,0 +1,69 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonSpan is the machine readable form of a Span. Lines and columns are
+// 1-based and columns count characters (Unicode code points), not bytes.
+// End is exclusive and on the same line as Start; all positions are zero
+// when there is no position.
+type jsonSpan struct {
+	File        string `json:"file"`
+	StartLine   int    `json:"startLine"`
+	StartColumn int    `json:"startColumn"`
+	EndLine     int    `json:"endLine"`
+	EndColumn   int    `json:"endColumn"`
+}
+
+type jsonRelated struct {
+	jsonSpan
+	Message string `json:"message"`
+}
+
+type jsonDiagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	jsonSpan
+	Notes   []string      `json:"notes"`
+	Help    string        `json:"help,omitempty"`
+	Related []jsonRelated `json:"related"`
+}
+
+func toJSONSpan(s Span) jsonSpan {
+	js := jsonSpan{File: s.File}
+	if s.Line == 0 {
+		return js
+	}
+	length := s.Length
+	if length < 1 {
+		length = 1
+	}
+	js.StartLine, js.StartColumn = s.Line, s.Column
+	js.EndLine, js.EndColumn = s.Line, s.Column+length
+	return js
+}
+
+// WriteJSON writes diagnostics to w as a JSON array, one object per
+// diagnostic, for use by editors and CI tools
+func WriteJSON(w io.Writer, diagnostics []Diagnostic) error {
+	out := make([]jsonDiagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		jd := jsonDiagnostic{
+			Severity: d.Severity.String(),
+			Code:     d.Code,
+			Message:  d.Message,
+			jsonSpan: toJSONSpan(d.Span),
+			Notes:    append([]string{}, d.Notes...),
+			Help:     d.Help,
+			Related:  []jsonRelated{},
+		}
+		for _, r := range d.Related {
+			jd.Related = append(jd.Related, jsonRelated{jsonSpan: toJSONSpan(r.Span), Message: r.Message})
+		}
+		out = append(out, jd)
+	}
+	return json.NewEncoder(w).Encode(out)
+}
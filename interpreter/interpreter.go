@@ -1,6 +1,7 @@
 package interpreter
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/asatale/go-lox/interpreter/tokenizer"
@@ -10,15 +11,45 @@ import (
 //Eof indicates end of file
 var EOFError = errors.New("End of data")
 
+// runError is an error with a stable code, see diagnostics.FromError
+type runError struct {
+	code string
+	msg  string
+}
+
+func (e *runError) Error() string {
+	return e.msg
+}
+
+func (e *runError) Code() string {
+	return e.code
+}
+
+// InterruptError is returned when execution is cancelled, e.g. by Ctrl-C
+var InterruptError error = &runError{code: "R001", msg: "Interrupted"}
+
 // Run is top level exec routine
 func Run(source io.Reader) error {
+	return RunContext(context.Background(), source)
+}
+
+// RunContext is like Run but stops with InterruptError once ctx is done
+func RunContext(ctx context.Context, source io.Reader) error {
+	return RunContextWithTable(ctx, source, tokenizer.InternTable{})
+}
+
+// RunContextWithTable is like RunContext but interns names in table, which
+// may be shared by all the inputs of a session
+func RunContextWithTable(ctx context.Context, source io.Reader, table tokenizer.InternTable) error {
 
-	tk := tokenizer.NewTokenizer(source)
+	tk := tokenizer.NewTokenizerWithTable(source, table)
 
 	for {
+		if ctx.Err() != nil {
+			return InterruptError
+		}
 		token, err := tk.GetToken()
 		if err != nil {
-			fmt.Println("Error: ", err)
 			return err
 		}
 		if token.Type == tokenizer.EOF {
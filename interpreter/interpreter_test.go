@@ -0,0 +1,23 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestRunContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := RunContext(ctx, bytes.NewBufferString(`print "never";`))
+	if err != InterruptError {
+		t.Errorf("Error expected: %v, Got: %v", InterruptError, err)
+	}
+}
+
+func TestInterruptErrorCode(t *testing.T) {
+	c, ok := InterruptError.(interface{ Code() string })
+	if !ok || c.Code() != "R001" {
+		t.Errorf("InterruptError code expected: R001")
+	}
+}
@@ -0,0 +1,33 @@
+package tokenizer
+
+import (
+	"io"
+)
+
+// IsComplete reports whether source can be handed to the interpreter as
+// is, i.e. it has no unclosed "(", "{" or "[" and does not end inside a
+// string or block comment. Other errors count as complete so that they get
+// reported, and so does a closer without an opener since no more input can
+// make the source valid.
+func IsComplete(source io.Reader) bool {
+	tk := NewTokenizer(source)
+	depth := 0
+	for {
+		token, err := tk.GetToken()
+		if err != nil {
+			tkErr, ok := err.(*TokenError)
+			return !ok || !tkErr.Incomplete
+		}
+		switch token.Type {
+		case LEFTPAREN, LEFTBRACE, LEFTBRACKET:
+			depth++
+		case RIGHTPAREN, RIGHTBRACE, RIGHTBRACKET:
+			if depth == 0 {
+				return true
+			}
+			depth--
+		case EOF:
+			return depth == 0
+		}
+	}
+}
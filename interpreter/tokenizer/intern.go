@@ -0,0 +1,21 @@
+package tokenizer
+
+// InternTable keeps one canonical copy of every identifier and string
+// literal seen by the tokenizers using it, so identical names share the
+// same backing storage and compare equal by pointer before falling back to
+// contents. A table only grows: it is collected once the tokenizers using
+// it are, so a caller sharing one across inputs with NewTokenizerWithTable
+// must bound it, e.g. by replacing it once it gets large. A table is not
+// safe for concurrent use.
+type InternTable map[string]string
+
+// intern returns the canonical string for b, adding it on first use.
+// Lookups with string(b) as the key do not allocate.
+func (it InternTable) intern(b []byte) string {
+	if s, ok := it[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	it[s] = s
+	return s
+}
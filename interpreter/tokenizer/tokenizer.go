@@ -7,26 +7,53 @@ import (
 	"regexp"
 	"strconv"
 	"unicode"
+	"unicode/utf8"
 )
 
-// TokenError is error
+// Codes identifying each kind of TokenError. They are stable so that tools
+// can rely on them.
+const (
+	CodeIOError             = "L001"
+	CodeUnterminatedString  = "L002"
+	CodeUnterminatedComment = "L003"
+	CodeInvalidIdentifier   = "L004"
+	CodeUnexpectedCharacter = "L005"
+)
+
+// TokenError is error reported for invalid source. Line and Column are
+// 1-based and point at the start of the offending text, which is Length
+// characters long. Columns count characters (Unicode code points), not
+// bytes, so a tab or an "é" is one column. Incomplete is set when the source ended in the middle of a
+// string or block comment.
 type TokenError struct {
-	msg  string
-	line int
+	Code       string
+	Msg        string
+	Line       int
+	Column     int
+	Length     int
+	Incomplete bool
 }
 
 func (e TokenError) Error() string {
-	return fmt.Sprintf("%s at %d", e.msg, e.line)
+	return fmt.Sprintf("%s at %d:%d", e.Msg, e.Line, e.Column)
+}
+
+func emitError(code string, s string, l int, c int, n int) error {
+	return &TokenError{Code: code, Msg: s, Line: l, Column: c, Length: n}
 }
 
-func emitError(s string, l int) error {
-	return &TokenError{msg: s, line: l}
+func emitIncomplete(code string, s string, l int, c int, n int) error {
+	return &TokenError{Code: code, Msg: s, Line: l, Column: c, Length: n, Incomplete: true}
 }
 
 type tokenizer struct {
-	source  *bytes.Buffer
-	index   int
-	lineNum int
+	source    *bytes.Buffer
+	data      []byte // all of source, for counting columns
+	index     int
+	size      int
+	lineNum   int
+	lineStart int
+	strings   InternTable
 }
 
 // Tokenizer is interface for token generation
@@ -36,73 +63,136 @@ type Tokenizer interface {
 
 // NewTokenizer creates new instance of tokenizer
 func NewTokenizer(source io.Reader) Tokenizer {
+	return NewTokenizerWithTable(source, InternTable{})
+}
+
+// NewTokenizerWithTable creates new instance of tokenizer that interns
+// identifiers and strings in table
+func NewTokenizerWithTable(source io.Reader, table InternTable) Tokenizer {
 	buf := bytes.NewBuffer([]byte{})
 	buf.ReadFrom(source)
 	return &tokenizer{
-		source: buf,
+		source:  buf,
+		data:    buf.Bytes(),
+		size:    buf.Len(),
+		lineNum: 1,
+		strings: table,
 	}
 }
 
+// column returns 1-based column of the next rune to be read, counted in
+// characters
+func (t *tokenizer) column() int {
+	return utf8.RuneCount(t.data[t.lineStart:t.size-t.source.Len()]) + 1
+}
+
+// newLine records that a "\n" has just been consumed
+func (t *tokenizer) newLine() {
+	t.lineNum++
+	t.lineStart = t.size - t.source.Len()
+}
+
 // GetToken returns next token
 func (t *tokenizer) GetToken() (Token, error) {
 Loop:
+	line, col := t.lineNum, t.column()
 	rune, _, err := t.source.ReadRune()
 	if err != nil {
 		if err == io.EOF {
 			return Token{
-				Type:  EOF,
-				Value: "EOF",
-				Line:  t.lineNum,
+				Type:   EOF,
+				Value:  "EOF",
+				Line:   line,
+				Column: col,
 			}, nil
 		}
-		return NullToken, emitError("Unknown IOError", t.lineNum)
+		return NullToken, emitError(CodeIOError, "Unknown IOError", line, col, 0)
 	}
 
 	switch string(rune) {
-	case "(", ")", "{", "}", ",", ".", "-", "+", ";", "*":
+	case "(", ")", "{", "}", "[", "]", ",", ";", ":", "&", "|", "^":
+		return Token{
+			Type:   _tokenMap[string(rune)],
+			Value:  string(rune),
+			Line:   line,
+			Column: col,
+		}, nil
+	case ".":
+		// "..." needs two runes of lookahead, more than UnreadRune allows
+		if bytes.HasPrefix(t.source.Bytes(), []byte("..")) {
+			t.source.Next(2)
+			return Token{
+				Type:   ELLIPSIS,
+				Value:  "...",
+				Line:   line,
+				Column: col,
+			}, nil
+		}
 		return Token{
-			Type:  _tokenMap[string(rune)],
-			Value: string(rune),
-			Line:  t.lineNum,
+			Type:   DOT,
+			Value:  string(rune),
+			Line:   line,
+			Column: col,
 		}, nil
 	case "\t", " ", "\n":
 		if string(rune) == "\n" {
-			t.lineNum++
+			t.newLine()
 		}
 		goto Loop
-	case "!", "=", ">", "<":
+	case "!", "=", ">", "<", "*", "~", "+", "-", "%", "?":
+		// Two character operators, e.g. "!=", "=>", "**", "~/", "++", "??"
+		if string(rune) == "~" && (bytes.HasPrefix(t.source.Bytes(), []byte("//")) ||
+			bytes.HasPrefix(t.source.Bytes(), []byte("/*"))) {
+			// "~" followed by a comment, not "~/"
+			return Token{
+				Type:   BITNOT,
+				Value:  string(rune),
+				Line:   line,
+				Column: col,
+			}, nil
+		}
 		nextChar, _, err := t.source.ReadRune()
-		if err != nil || string(nextChar) != "=" {
+		if _, ok := _tokenMap[string(rune)+string(nextChar)]; err != nil || !ok {
 			if err == nil {
 				t.source.UnreadRune()
 			}
 			return Token{
-				Type:  _tokenMap[string(rune)],
-				Value: string(rune),
-				Line:  t.lineNum,
+				Type:   _tokenMap[string(rune)],
+				Value:  string(rune),
+				Line:   line,
+				Column: col,
 			}, nil
 		}
 		return Token{
-			Type:  _tokenMap[string(rune)+string(nextChar)],
-			Value: string(rune) + string(nextChar),
-			Line:  t.lineNum,
+			Type:   _tokenMap[string(rune)+string(nextChar)],
+			Value:  string(rune) + string(nextChar),
+			Line:   line,
+			Column: col,
 		}, nil
 	case "/":
 		nextChar, _, err := t.source.ReadRune()
 		if err == nil {
 			switch {
 			case string(nextChar) == "/":
-				return t.singleLineComment()
+				return t.singleLineComment(line, col)
 			case string(nextChar) == "*":
-				return t.multiLineComment()
+				return t.multiLineComment(line, col)
+			case string(nextChar) == "=":
+				return Token{
+					Type:   DIVIDEEQUAL,
+					Value:  "/=",
+					Line:   line,
+					Column: col,
+				}, nil
 			default:
 				t.source.UnreadRune()
 			}
 		}
 		return Token{
-			Type:  _tokenMap[string(rune)],
-			Value: string(rune),
-			Line:  t.lineNum,
+			Type:   _tokenMap[string(rune)],
+			Value:  string(rune),
+			Line:   line,
+			Column: col,
 		}, nil
 
 	case `"`:
@@ -111,28 +201,27 @@ Loop:
 			nextChar, _, err := t.source.ReadRune()
 			switch {
 			case err != nil:
-				return NullToken, emitError("Unterminated \"", t.lineNum)
+				return NullToken, emitIncomplete(CodeUnterminatedString, "Unterminated \"", line, col, 1)
 			case string(nextChar) == `"`:
 				return Token{
-					Type:  STRING,
-					Value: b.String(),
-					Line:  t.lineNum,
+					Type:   STRING,
+					Value:  t.strings.intern(b.Bytes()),
+					Line:   line,
+					Column: col,
 				}, nil
 			case string(nextChar) == "\n":
-				t.lineNum++
+				t.newLine()
 			}
 			b.WriteRune(nextChar)
 		}
 	default:
 		t.source.UnreadRune()
-		tk, err := t.getComplexToken()
+		tk, err := t.getComplexToken(line, col)
 		return tk, err
 	}
-	// Should not reach here
-	return NullToken, emitError("TokenError", t.lineNum)
 }
 
-func (t *tokenizer) getComplexToken() (Token, error) {
+func (t *tokenizer) getComplexToken(line, col int) (Token, error) {
 	var b bytes.Buffer
 
 	for t.source.Len() > 0 {
@@ -150,26 +239,35 @@ func (t *tokenizer) getComplexToken() (Token, error) {
 		}
 	}
 
+	if b.Len() == 0 {
+		// Not the start of any token, skip it so that scanning can go on
+		r, _, _ := t.source.ReadRune()
+		return NullToken, emitError(CodeUnexpectedCharacter, fmt.Sprintf("Unexpected character %q", r), line, col, 1)
+	}
+
 	if _, ok := _tokenMap[b.String()]; ok {
 		return Token{
-			Type:  _tokenMap[b.String()],
-			Value: b.String(),
-			Line:  t.lineNum,
+			Type:   _tokenMap[b.String()],
+			Value:  b.String(),
+			Line:   line,
+			Column: col,
 		}, nil
 	}
 
 	if _, err := strconv.ParseFloat(b.String(), 64); err == nil {
 		return Token{
-			Type:  NUMBER,
-			Value: b.String(),
-			Line:  t.lineNum,
+			Type:   NUMBER,
+			Value:  b.String(),
+			Line:   line,
+			Column: col,
 		}, nil
 	}
 	if _, err := strconv.ParseInt(b.String(), 10, 64); err == nil {
 		return Token{
-			Type:  NUMBER,
-			Value: b.String(),
-			Line:  t.lineNum,
+			Type:   NUMBER,
+			Value:  b.String(),
+			Line:   line,
+			Column: col,
 		}, nil
 	}
 
@@ -178,50 +276,53 @@ func (t *tokenizer) getComplexToken() (Token, error) {
 
 	if result {
 		return Token{
-			Type:  IDENTIFIER,
-			Value: b.String(),
-			Line:  t.lineNum,
+			Type:   IDENTIFIER,
+			Value:  t.strings.intern(b.Bytes()),
+			Line:   line,
+			Column: col,
 		}, nil
 	}
 
-	return NullToken, emitError(fmt.Sprintf("Invalid identifier \"%s\"", b.String()), t.lineNum)
+	return NullToken, emitError(CodeInvalidIdentifier, fmt.Sprintf("Invalid identifier \"%s\"", b.String()), line, col, utf8.RuneCount(b.Bytes()))
 }
 
-func (t *tokenizer) singleLineComment() (Token, error) {
+func (t *tokenizer) singleLineComment(line, col int) (Token, error) {
 	var b bytes.Buffer
 	for {
 		nextChar, _, err := t.source.ReadRune()
 		if err != nil || string(nextChar) == "\n" {
-			t.lineNum++
+			if err == nil {
+				t.newLine()
+			}
 			return Token{
-				Type:  COMMENT,
-				Value: b.String(),
-				Line:  t.lineNum - 1,
+				Type:   COMMENT,
+				Value:  b.String(),
+				Line:   line,
+				Column: col,
 			}, nil
 		}
 		b.WriteRune(nextChar)
 	}
-	return NullToken, emitError("TokenError", t.lineNum)
 }
 
-func (t *tokenizer) multiLineComment() (Token, error) {
+func (t *tokenizer) multiLineComment(line, col int) (Token, error) {
 	var b bytes.Buffer
-	lineno := t.lineNum
 	for {
 		nextChar, _, err := t.source.ReadRune()
 		switch {
 		case err != nil:
-			return NullToken, emitError("Unterminated block comment", t.lineNum)
+			return NullToken, emitIncomplete(CodeUnterminatedComment, "Unterminated block comment", line, col, 2)
 		case string(nextChar) == "\n":
-			t.lineNum++
+			t.newLine()
 		case string(nextChar) == "*":
 			nextChar, _, err := t.source.ReadRune()
 			if err == nil {
 				if string(nextChar) == "/" {
 					return Token{
-						Type:  COMMENT,
-						Value: b.String(),
-						Line:  lineno,
+						Type:   COMMENT,
+						Value:  b.String(),
+						Line:   line,
+						Column: col,
 					}, nil
 				} else {
 					t.source.UnreadRune()
@@ -230,5 +331,4 @@ func (t *tokenizer) multiLineComment() (Token, error) {
 		}
 		b.WriteRune(nextChar)
 	}
-	return NullToken, emitError("Unterminated block comment", t.lineNum)
 }
@@ -2,7 +2,9 @@ package tokenizer
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
+	"unsafe"
 )
 
 type testCase struct {
@@ -37,6 +39,48 @@ func TestSingleTokensTests(t *testing.T) {
 			result:        []TokenType{LEFTPAREN, RIGHTPAREN, LEFTBRACE, RIGHTBRACE, COMMA, DOT, MINUS, PLUS, SEMICOLON, DIVIDE, MULTIPLY, BANG, BANGEQUAL, EQUAL, DOUBLEEQUAL, GREATER, GREATEREQUAL, LESS, LESSEQUAL},
 			errorExpected: false,
 		},
+		{
+			description:   "Test for brackets ",
+			source:        bytes.NewBufferString(`[ ] xs[0] = [1]`),
+			result:        []TokenType{LEFTBRACKET, RIGHTBRACKET, IDENTIFIER, LEFTBRACKET, NUMBER, RIGHTBRACKET, EQUAL, LEFTBRACKET, NUMBER, RIGHTBRACKET},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for map literal colon ",
+			source:        bytes.NewBufferString(`{"a": 1, b: 2}`),
+			result:        []TokenType{LEFTBRACE, STRING, COLON, NUMBER, COMMA, IDENTIFIER, COLON, NUMBER, RIGHTBRACE},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for arrow ",
+			source:        bytes.NewBufferString(`(a) => a >= 0 == true`),
+			result:        []TokenType{LEFTPAREN, IDENTIFIER, RIGHTPAREN, ARROW, IDENTIFIER, GREATEREQUAL, NUMBER, DOUBLEEQUAL, TRUE},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for ellipsis ",
+			source:        bytes.NewBufferString(`... f(...rest) .. .`),
+			result:        []TokenType{ELLIPSIS, IDENTIFIER, LEFTPAREN, ELLIPSIS, IDENTIFIER, RIGHTPAREN, DOT, DOT, DOT},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for arithmetic and bitwise operators ",
+			source:        bytes.NewBufferString(`% ** ~/ & | ^ ~ << >>`),
+			result:        []TokenType{MODULO, POWER, INTDIVIDE, BITAND, BITOR, BITXOR, BITNOT, LEFTSHIFT, RIGHTSHIFT},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for compound assignment operators ",
+			source:        bytes.NewBufferString(`+= -= *= /= %= ++ --`),
+			result:        []TokenType{PLUSEQUAL, MINUSEQUAL, MULTIPLYEQUAL, DIVIDEEQUAL, MODULOEQUAL, INCREMENT, DECREMENT},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for conditional operators ",
+			source:        bytes.NewBufferString(`? : ?? ?.`),
+			result:        []TokenType{QUESTION, COLON, DOUBLEQUESTION, QUESTIONDOT},
+			errorExpected: false,
+		},
 		{
 			description:   "Test for Identifier",
 			source:        bytes.NewBufferString("i count Count countMin test_iteration i32"),
@@ -61,6 +105,24 @@ func TestSingleTokensTests(t *testing.T) {
 			result:        []TokenType{AND, OR, CLASS, ELSE, FALSE, FUN, FOR, IF, NIL, PRINT, RETURN, SUPER, THIS, TRUE, VAR, WHILE},
 			errorExpected: false,
 		},
+		{
+			description:   "Test for in keyword",
+			source:        bytes.NewBufferString(`for (x in xs) inside`),
+			result:        []TokenType{FOR, LEFTPAREN, IDENTIFIER, IN, IDENTIFIER, RIGHTPAREN, IDENTIFIER},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for loop control keywords",
+			source:        bytes.NewBufferString(`break continue breaks continued`),
+			result:        []TokenType{BREAK, CONTINUE, IDENTIFIER, IDENTIFIER},
+			errorExpected: false,
+		},
+		{
+			description:   "Test for exception keywords",
+			source:        bytes.NewBufferString(`throw try catch finally tryAgain`),
+			result:        []TokenType{THROW, TRY, CATCH, FINALLY, IDENTIFIER},
+			errorExpected: false,
+		},
 		{
 			description:   "Test for empty source ",
 			source:        bytes.NewBufferString(``),
@@ -119,6 +181,74 @@ func TestMixedTokensTests(t *testing.T) {
 				IDENTIFIER, DIVIDE, IDENTIFIER, SEMICOLON},
 			errorExpected: false,
 		},
+		{
+			description: "Testing operations % ** ~/ and bitwise operators",
+			source: bytes.NewBufferString(`
+         rem = a % b;
+         pow = 2**10 * 3;
+         quot = a ~/ b / c;
+         mask = (flags & ~1) | 4 ^ bits;
+         shifted = 1 << 4 >> 2 < 3 <= 4;
+      `),
+			result: []TokenType{
+				IDENTIFIER, EQUAL, IDENTIFIER, MODULO, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, EQUAL, NUMBER, POWER, NUMBER, MULTIPLY, NUMBER, SEMICOLON,
+				IDENTIFIER, EQUAL, IDENTIFIER, INTDIVIDE, IDENTIFIER, DIVIDE, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, EQUAL, LEFTPAREN, IDENTIFIER, BITAND, BITNOT, NUMBER, RIGHTPAREN, BITOR, NUMBER, BITXOR, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, EQUAL, NUMBER, LEFTSHIFT, NUMBER, RIGHTSHIFT, NUMBER, LESS, NUMBER, LESSEQUAL, NUMBER, SEMICOLON},
+			errorExpected: false,
+		},
+		{
+			description: "Testing compound assignment and increment/decrement",
+			source: bytes.NewBufferString(`
+         total += price * qty;
+         obj.count -= 1;
+         xs[i] *= 2;
+         avg /= n; // average
+         idx %= len;
+         i++; --j;
+         a - -b;
+      `),
+			result: []TokenType{
+				IDENTIFIER, PLUSEQUAL, IDENTIFIER, MULTIPLY, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, DOT, IDENTIFIER, MINUSEQUAL, NUMBER, SEMICOLON,
+				IDENTIFIER, LEFTBRACKET, IDENTIFIER, RIGHTBRACKET, MULTIPLYEQUAL, NUMBER, SEMICOLON,
+				IDENTIFIER, DIVIDEEQUAL, IDENTIFIER, SEMICOLON, COMMENT,
+				IDENTIFIER, MODULOEQUAL, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, INCREMENT, SEMICOLON, DECREMENT, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, MINUS, MINUS, IDENTIFIER, SEMICOLON},
+			errorExpected: false,
+		},
+		{
+			description: "Testing ternary, null-coalescing and optional chaining",
+			source: bytes.NewBufferString(`
+         var sign = n < 0 ? "-" : "+";
+         var name = user?.name ?? "anonymous";
+         user?.greet();
+         var x = a?b:c;
+      `),
+			result: []TokenType{
+				VAR, IDENTIFIER, EQUAL, IDENTIFIER, LESS, NUMBER, QUESTION, STRING, COLON, STRING, SEMICOLON,
+				VAR, IDENTIFIER, EQUAL, IDENTIFIER, QUESTIONDOT, IDENTIFIER, DOUBLEQUESTION, STRING, SEMICOLON,
+				IDENTIFIER, QUESTIONDOT, IDENTIFIER, LEFTPAREN, RIGHTPAREN, SEMICOLON,
+				VAR, IDENTIFIER, EQUAL, IDENTIFIER, QUESTION, IDENTIFIER, COLON, IDENTIFIER, SEMICOLON},
+			errorExpected: false,
+		},
+		{
+			description: "Testing ~ followed by a comment",
+			source: bytes.NewBufferString(`
+         m = ~/* flip */ x;
+         m = ~// flip
+           x;
+         q = a ~/ b;
+      `),
+			result: []TokenType{
+				IDENTIFIER, EQUAL, BITNOT, COMMENT, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, EQUAL, BITNOT, COMMENT,
+				IDENTIFIER, SEMICOLON,
+				IDENTIFIER, EQUAL, IDENTIFIER, INTDIVIDE, IDENTIFIER, SEMICOLON},
+			errorExpected: false,
+		},
 		{
 			description: "Testing unary operator ",
 			source: bytes.NewBufferString(`
@@ -311,6 +441,119 @@ func TestMixedTokensTests(t *testing.T) {
 			},
 			errorExpected: false,
 		},
+		{
+			description: "Lists",
+			source: bytes.NewBufferString(`
+        var xs = [1, 2, 3];
+        xs[0] = xs[len - 1];
+        var empty = [];
+     `),
+			result: []TokenType{
+				VAR, IDENTIFIER, EQUAL, LEFTBRACKET, NUMBER, COMMA, NUMBER, COMMA, NUMBER, RIGHTBRACKET, SEMICOLON,
+				IDENTIFIER, LEFTBRACKET, NUMBER, RIGHTBRACKET, EQUAL, IDENTIFIER, LEFTBRACKET, IDENTIFIER, MINUS, NUMBER, RIGHTBRACKET, SEMICOLON,
+				VAR, IDENTIFIER, EQUAL, LEFTBRACKET, RIGHTBRACKET, SEMICOLON,
+			},
+			errorExpected: false,
+		},
+		{
+			description: "Maps",
+			source: bytes.NewBufferString(`
+        var ages = {"alice": 30, "bob": 25};
+        print ages["alice"];
+        var empty = {};
+     `),
+			result: []TokenType{
+				VAR, IDENTIFIER, EQUAL, LEFTBRACE, STRING, COLON, NUMBER, COMMA, STRING, COLON, NUMBER, RIGHTBRACE, SEMICOLON,
+				PRINT, IDENTIFIER, LEFTBRACKET, STRING, RIGHTBRACKET, SEMICOLON,
+				VAR, IDENTIFIER, EQUAL, LEFTBRACE, RIGHTBRACE, SEMICOLON,
+			},
+			errorExpected: false,
+		},
+		{
+			description: "For in loops",
+			source: bytes.NewBufferString(`
+        for (x in range(0, 10, 2)) {
+          print x;
+        }
+        for (item in inventory) print item;
+     `),
+			result: []TokenType{
+				FOR, LEFTPAREN, IDENTIFIER, IN, IDENTIFIER, LEFTPAREN, NUMBER, COMMA, NUMBER, COMMA, NUMBER, RIGHTPAREN, RIGHTPAREN, LEFTBRACE,
+				PRINT, IDENTIFIER, SEMICOLON,
+				RIGHTBRACE,
+				FOR, LEFTPAREN, IDENTIFIER, IN, IDENTIFIER, RIGHTPAREN, PRINT, IDENTIFIER, SEMICOLON,
+			},
+			errorExpected: false,
+		},
+		{
+			description: "Break and continue",
+			source: bytes.NewBufferString(`
+        while (true) {
+          if (done) break;
+          continue;
+        }
+     `),
+			result: []TokenType{
+				WHILE, LEFTPAREN, TRUE, RIGHTPAREN, LEFTBRACE,
+				IF, LEFTPAREN, IDENTIFIER, RIGHTPAREN, BREAK, SEMICOLON,
+				CONTINUE, SEMICOLON,
+				RIGHTBRACE,
+			},
+			errorExpected: false,
+		},
+		{
+			description: "Exceptions",
+			source: bytes.NewBufferString(`
+        try {
+          throw Error("bad");
+        } catch (e) {
+          print e.message;
+        } finally {
+          close();
+        }
+     `),
+			result: []TokenType{
+				TRY, LEFTBRACE,
+				THROW, IDENTIFIER, LEFTPAREN, STRING, RIGHTPAREN, SEMICOLON,
+				RIGHTBRACE, CATCH, LEFTPAREN, IDENTIFIER, RIGHTPAREN, LEFTBRACE,
+				PRINT, IDENTIFIER, DOT, IDENTIFIER, SEMICOLON,
+				RIGHTBRACE, FINALLY, LEFTBRACE,
+				IDENTIFIER, LEFTPAREN, RIGHTPAREN, SEMICOLON,
+				RIGHTBRACE,
+			},
+			errorExpected: false,
+		},
+		{
+			description: "Anonymous functions",
+			source: bytes.NewBufferString(`
+        var add = fun (a, b) { return a + b; };
+        sort(xs, (a, b) => a < b);
+        var ok = x >= y;
+     `),
+			result: []TokenType{
+				VAR, IDENTIFIER, EQUAL, FUN, LEFTPAREN, IDENTIFIER, COMMA, IDENTIFIER, RIGHTPAREN, LEFTBRACE,
+				RETURN, IDENTIFIER, PLUS, IDENTIFIER, SEMICOLON, RIGHTBRACE, SEMICOLON,
+				IDENTIFIER, LEFTPAREN, IDENTIFIER, COMMA, LEFTPAREN, IDENTIFIER, COMMA, IDENTIFIER, RIGHTPAREN, ARROW, IDENTIFIER, LESS, IDENTIFIER, RIGHTPAREN, SEMICOLON,
+				VAR, IDENTIFIER, EQUAL, IDENTIFIER, GREATEREQUAL, IDENTIFIER, SEMICOLON,
+			},
+			errorExpected: false,
+		},
+		{
+			description: "Default, rest and spread arguments",
+			source: bytes.NewBufferString(`
+        fun greet(name, greeting = "hi", ...rest) {}
+        log(...args);
+        a..b;
+        obj.field....x;
+     `),
+			result: []TokenType{
+				FUN, IDENTIFIER, LEFTPAREN, IDENTIFIER, COMMA, IDENTIFIER, EQUAL, STRING, COMMA, ELLIPSIS, IDENTIFIER, RIGHTPAREN, LEFTBRACE, RIGHTBRACE,
+				IDENTIFIER, LEFTPAREN, ELLIPSIS, IDENTIFIER, RIGHTPAREN, SEMICOLON,
+				IDENTIFIER, DOT, DOT, IDENTIFIER, SEMICOLON,
+				IDENTIFIER, DOT, IDENTIFIER, ELLIPSIS, DOT, IDENTIFIER, SEMICOLON,
+			},
+			errorExpected: false,
+		},
 		{
 			description: "Block comment",
 			source: bytes.NewBufferString(`
@@ -371,6 +614,184 @@ func TestInvalidTokensTests(t *testing.T) {
 			},
 			errorExpected: true,
 		},
+		{
+			description: "Test unexpected character",
+			source: bytes.NewBufferString(`
+        @;
+      `),
+			result: []TokenType{
+				NULLTOKEN,
+			},
+			errorExpected: true,
+		},
 	}
 	runTestcases(testCases, t)
 }
+
+func TestUnexpectedCharacterSkipped(t *testing.T) {
+	tk := NewTokenizer(bytes.NewBufferString("@#;"))
+	for _, expected := range []string{"Unexpected character '@'", "Unexpected character '#'"} {
+		_, err := tk.GetToken()
+		tkErr, ok := err.(*TokenError)
+		if !ok || tkErr.Msg != expected || tkErr.Length != 1 {
+			t.Errorf("Error expected: %q of length 1, Got: %#v", expected, err)
+		}
+	}
+	if token, err := tk.GetToken(); err != nil || token.Type != SEMICOLON {
+		t.Errorf("Token expected: SEMICOLON, Got: %v, %v", token.Type, err)
+	}
+}
+
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestInternedStrings(t *testing.T) {
+	table := InternTable{}
+	testCases := []struct {
+		source string
+		size   int
+	}{
+		{`count "count" count "count" other`, 2},
+		{`other count; var total = count;`, 3},
+		{`"total" total 12 + 3;`, 3},
+	}
+	// first holds the backing pointer of the first token seen for a name
+	first := map[string]uintptr{}
+	for _, testCase := range testCases {
+		tk := NewTokenizerWithTable(bytes.NewBufferString(testCase.source), table)
+		for {
+			token, err := tk.GetToken()
+			if err != nil {
+				t.Fatalf("Error expected: nil, Got: %v", err)
+			}
+			if token.Type == EOF {
+				break
+			}
+			if token.Type != IDENTIFIER && token.Type != STRING {
+				continue
+			}
+			p, ok := first[token.Value]
+			if !ok {
+				first[token.Value] = stringData(token.Value)
+			} else if p != stringData(token.Value) {
+				t.Errorf("%q: %q not interned", testCase.source, token.Value)
+			}
+		}
+		if len(table) != testCase.size {
+			t.Errorf("%q: Table size expected: %v, Got: %v (%v)", testCase.source, testCase.size, len(table), table)
+		}
+	}
+	if first["count"] == first["other"] {
+		t.Errorf("Distinct strings %q and %q share storage", "count", "other")
+	}
+
+	// A tokenizer with a table of its own does not share storage
+	tk := NewTokenizer(bytes.NewBufferString(`count`))
+	token, _ := tk.GetToken()
+	if stringData(token.Value) == first["count"] {
+		t.Errorf("%q shared across tables", token.Value)
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	tk := NewTokenizer(bytes.NewBufferString("var a = 1;\n  print \"two\nlines\" a;\n/* x */ b"))
+	expected := []struct {
+		tokenType TokenType
+		line      int
+		column    int
+	}{
+		{VAR, 1, 1}, {IDENTIFIER, 1, 5}, {EQUAL, 1, 7}, {NUMBER, 1, 9}, {SEMICOLON, 1, 10},
+		{PRINT, 2, 3}, {STRING, 2, 9}, {IDENTIFIER, 3, 8}, {SEMICOLON, 3, 9},
+		{COMMENT, 4, 1}, {IDENTIFIER, 4, 9}, {EOF, 4, 10},
+	}
+	for _, e := range expected {
+		token, err := tk.GetToken()
+		if err != nil {
+			t.Fatalf("Error expected: nil, Got: %v", err)
+		}
+		if token.Type != e.tokenType || token.Line != e.line || token.Column != e.column {
+			t.Errorf("Token expected: %v at %d:%d, Got: %v at %d:%d",
+				e.tokenType, e.line, e.column, token.Type, token.Line, token.Column)
+		}
+	}
+}
+
+func TestTokenErrorPositions(t *testing.T) {
+	testCases := []struct {
+		source string
+		code   string
+		line   int
+		column int
+	}{
+		{"var s =\n  \"unterminated", CodeUnterminatedString, 2, 3},
+		{"a;\n/* unterminated\n", CodeUnterminatedComment, 2, 1},
+		{"x = 12abc;", CodeInvalidIdentifier, 1, 5},
+		{"print @;", CodeUnexpectedCharacter, 1, 7},
+		{`var s = "héllo"; x = 2abc;`, CodeInvalidIdentifier, 1, 22},
+		{"\"日本\"\t@", CodeUnexpectedCharacter, 1, 6},
+	}
+	for _, testCase := range testCases {
+		tk := NewTokenizer(bytes.NewBufferString(testCase.source))
+		var err error
+		for err == nil {
+			var token Token
+			token, err = tk.GetToken()
+			if token.Type == EOF {
+				break
+			}
+		}
+		tkErr, ok := err.(*TokenError)
+		if !ok {
+			t.Errorf("%q: TokenError expected, Got: %v", testCase.source, err)
+			continue
+		}
+		if tkErr.Code != testCase.code || tkErr.Line != testCase.line || tkErr.Column != testCase.column {
+			t.Errorf("%q: Error expected %v at %d:%d, Got: %v %v", testCase.source, testCase.code, testCase.line, testCase.column, tkErr.Code, tkErr)
+		}
+	}
+}
+
+func TestIsComplete(t *testing.T) {
+	testCases := []struct {
+		source   string
+		complete bool
+	}{
+		{`print "Hello";`, true},
+		{``, true},
+		{`fun add(a, b) {`, false},
+		{"fun add(a, b) {\n  return a + b;\n}", true},
+		{`makeBreakfast(bacon,`, false},
+		{`var xs = [1, 2,`, false},
+		{`var xs = [1, 2];`, true},
+		{`print "Hello`, false},
+		{`/* a block`, false},
+		{"/* a block\n comment */", true},
+		{`print "}";`, true},
+		{`}`, true},
+		{`print 1; )`, true},
+		{`}{`, true},
+		{`) (`, true},
+		{`} {}`, true},
+		{`f(a)) + g(`, true},
+		{`} "open`, true},
+		{`f(a) + g(`, false},
+		{`var x = 2abc {`, true},
+	}
+	for _, testCase := range testCases {
+		if got := IsComplete(bytes.NewBufferString(testCase.source)); got != testCase.complete {
+			t.Errorf("%q: Complete expected: %v, Got: %v", testCase.source, testCase.complete, got)
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	keywords := Keywords()
+	expected := []string{
+		"and", "break", "catch", "class", "continue", "else", "false", "finally", "for", "fun", "if", "in",
+		"nil", "or", "print", "return", "super", "this", "throw", "true", "try", "var", "while",
+	}
+	if !reflect.DeepEqual(keywords, expected) {
+		t.Errorf("Keywords expected: %v, Got: %v", expected, keywords)
+	}
+}
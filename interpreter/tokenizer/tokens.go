@@ -2,52 +2,85 @@ package tokenizer
 
 import (
 	"fmt"
+	"sort"
+	"unicode"
 )
 
 type TokenType int
 
 const (
-	NULLTOKEN    TokenType = iota // No Token
-	LEFTPAREN                     // "("
-	RIGHTPAREN                    // ")"
-	LEFTBRACE                     // "{"
-	RIGHTBRACE                    // "}"
-	COMMA                         // ","
-	DOT                           // "."
-	MINUS                         // "-"
-	PLUS                          // "+"
-	SEMICOLON                     // ";"
-	DIVIDE                        // "/"
-	MULTIPLY                      // "*"
-	BANG                          // "!"
-	BANGEQUAL                     // "!="
-	EQUAL                         // "="
-	DOUBLEEQUAL                   // "=="
-	GREATER                       // ">"
-	GREATEREQUAL                  // ">="
-	LESS                          // "<"
-	LESSEQUAL                     // "<="
-	IDENTIFIER                    // E.g. "i"
-	STRING                        // E.g. "Hello"
-	NUMBER                        // E.g. 42, 3.14
-	AND                           // &&
-	OR                            // "||"
-	CLASS                         // "class"
-	ELSE                          // "else"
-	FALSE                         // "false"
-	FUN                           // "fun"
-	FOR                           // "for"
-	IF                            // "if"
-	NIL                           // "nil"
-	PRINT                         // "print"
-	RETURN                        // "return"
-	SUPER                         // "super"
-	THIS                          // "this"
-	TRUE                          // "true"
-	VAR                           // "var"
-	WHILE                         // "while"
-	EOF                           // "EOF"
-	COMMENT                       // "// This is a comment"
+	NULLTOKEN      TokenType = iota // No Token
+	LEFTPAREN                       // "("
+	RIGHTPAREN                      // ")"
+	LEFTBRACE                       // "{"
+	RIGHTBRACE                      // "}"
+	LEFTBRACKET                     // "["
+	RIGHTBRACKET                    // "]"
+	COMMA                           // ","
+	DOT                             // "."
+	ELLIPSIS                        // "..."
+	MINUS                           // "-"
+	PLUS                            // "+"
+	SEMICOLON                       // ";"
+	COLON                           // ":"
+	QUESTION                        // "?"
+	DOUBLEQUESTION                  // "??"
+	QUESTIONDOT                     // "?."
+	DIVIDE                          // "/"
+	MULTIPLY                        // "*"
+	MODULO                          // "%"
+	POWER                           // "**"
+	INTDIVIDE                       // "~/"
+	BITAND                          // "&"
+	BITOR                           // "|"
+	BITXOR                          // "^"
+	BITNOT                          // "~"
+	LEFTSHIFT                       // "<<"
+	RIGHTSHIFT                      // ">>"
+	PLUSEQUAL                       // "+="
+	MINUSEQUAL                      // "-="
+	MULTIPLYEQUAL                   // "*="
+	DIVIDEEQUAL                     // "/="
+	MODULOEQUAL                     // "%="
+	INCREMENT                       // "++"
+	DECREMENT                       // "--"
+	BANG                            // "!"
+	BANGEQUAL                       // "!="
+	EQUAL                           // "="
+	DOUBLEEQUAL                     // "=="
+	GREATER                         // ">"
+	GREATEREQUAL                    // ">="
+	LESS                            // "<"
+	LESSEQUAL                       // "<="
+	ARROW                           // "=>"
+	IDENTIFIER                      // E.g. "i"
+	STRING                          // E.g. "Hello"
+	NUMBER                          // E.g. 42, 3.14
+	AND                             // &&
+	OR                              // "||"
+	BREAK                           // "break"
+	CATCH                           // "catch"
+	CLASS                           // "class"
+	CONTINUE                        // "continue"
+	ELSE                            // "else"
+	FALSE                           // "false"
+	FINALLY                         // "finally"
+	FUN                             // "fun"
+	FOR                             // "for"
+	IF                              // "if"
+	IN                              // "in"
+	NIL                             // "nil"
+	PRINT                           // "print"
+	RETURN                          // "return"
+	SUPER                           // "super"
+	THIS                            // "this"
+	THROW                           // "throw"
+	TRUE                            // "true"
+	TRY                             // "try"
+	VAR                             // "var"
+	WHILE                           // "while"
+	EOF                             // "EOF"
+	COMMENT                         // "// This is a comment"
 )
 
 func (t TokenType) String() string {
@@ -62,20 +95,66 @@ func (t TokenType) String() string {
 		return "{"
 	case RIGHTBRACE:
 		return "}"
+	case LEFTBRACKET:
+		return "["
+	case RIGHTBRACKET:
+		return "]"
 	case COMMA:
 		return ","
 	case DOT:
 		return "."
+	case ELLIPSIS:
+		return "..."
 	case MINUS:
 		return "-"
 	case PLUS:
 		return "+"
 	case SEMICOLON:
 		return ";"
+	case COLON:
+		return ":"
+	case QUESTION:
+		return "?"
+	case DOUBLEQUESTION:
+		return "??"
+	case QUESTIONDOT:
+		return "?."
 	case DIVIDE:
 		return "/"
 	case MULTIPLY:
 		return "*"
+	case MODULO:
+		return "%"
+	case POWER:
+		return "**"
+	case INTDIVIDE:
+		return "~/"
+	case BITAND:
+		return "&"
+	case BITOR:
+		return "|"
+	case BITXOR:
+		return "^"
+	case BITNOT:
+		return "~"
+	case LEFTSHIFT:
+		return "<<"
+	case RIGHTSHIFT:
+		return ">>"
+	case PLUSEQUAL:
+		return "+="
+	case MINUSEQUAL:
+		return "-="
+	case MULTIPLYEQUAL:
+		return "*="
+	case DIVIDEEQUAL:
+		return "/="
+	case MODULOEQUAL:
+		return "%="
+	case INCREMENT:
+		return "++"
+	case DECREMENT:
+		return "--"
 	case BANG:
 		return "!"
 	case BANGEQUAL:
@@ -92,6 +171,8 @@ func (t TokenType) String() string {
 		return "<"
 	case LESSEQUAL:
 		return "<="
+	case ARROW:
+		return "=>"
 	case IDENTIFIER:
 		return "identifier"
 	case STRING:
@@ -102,18 +183,28 @@ func (t TokenType) String() string {
 		return "and"
 	case OR:
 		return "or"
+	case BREAK:
+		return "break"
+	case CATCH:
+		return "catch"
 	case CLASS:
 		return "class"
+	case CONTINUE:
+		return "continue"
 	case ELSE:
 		return "else"
 	case FALSE:
 		return "false"
+	case FINALLY:
+		return "finally"
 	case FUN:
 		return "fun"
 	case FOR:
 		return "for"
 	case IF:
 		return "if"
+	case IN:
+		return "in"
 	case NIL:
 		return "nil"
 	case PRINT:
@@ -124,8 +215,12 @@ func (t TokenType) String() string {
 		return "super"
 	case THIS:
 		return "this"
+	case THROW:
+		return "throw"
 	case TRUE:
 		return "true"
+	case TRY:
+		return "try"
 	case VAR:
 		return "var"
 	case WHILE:
@@ -139,9 +234,10 @@ func (t TokenType) String() string {
 }
 
 type Token struct {
-	Type  TokenType
-	Value string
-	Line  int
+	Type   TokenType
+	Value  string
+	Line   int
+	Column int // 1-based, in characters like TokenError.Column
 }
 
 var NullToken = Token{
@@ -149,43 +245,86 @@ var NullToken = Token{
 }
 
 func (t Token) String() string {
-	return fmt.Sprintf("Token{ Type:%v, Value: %v, Line: %v}", t.Type, t.Value, t.Line)
+	return fmt.Sprintf("Token{ Type:%v, Value: %v, Line: %v, Column: %v}", t.Type, t.Value, t.Line, t.Column)
 }
 
 var _tokenMap = map[string]TokenType{
-	"(":      LEFTPAREN,
-	")":      RIGHTPAREN,
-	"{":      LEFTBRACE,
-	"}":      RIGHTBRACE,
-	",":      COMMA,
-	".":      DOT,
-	"-":      MINUS,
-	"+":      PLUS,
-	";":      SEMICOLON,
-	"/":      DIVIDE,
-	"*":      MULTIPLY,
-	"!":      BANG,
-	"=":      EQUAL,
-	">":      GREATER,
-	"<":      LESS,
-	"!=":     BANGEQUAL,
-	"==":     DOUBLEEQUAL,
-	">=":     GREATEREQUAL,
-	"<=":     LESSEQUAL,
-	"and":    AND,
-	"or":     OR,
-	"class":  CLASS,
-	"else":   ELSE,
-	"false":  FALSE,
-	"fun":    FUN,
-	"for":    FOR,
-	"if":     IF,
-	"nil":    NIL,
-	"print":  PRINT,
-	"return": RETURN,
-	"super":  SUPER,
-	"this":   THIS,
-	"true":   TRUE,
-	"var":    VAR,
-	"while":  WHILE,
+	"(":        LEFTPAREN,
+	")":        RIGHTPAREN,
+	"{":        LEFTBRACE,
+	"}":        RIGHTBRACE,
+	"[":        LEFTBRACKET,
+	"]":        RIGHTBRACKET,
+	",":        COMMA,
+	".":        DOT,
+	"...":      ELLIPSIS,
+	"-":        MINUS,
+	"+":        PLUS,
+	";":        SEMICOLON,
+	":":        COLON,
+	"?":        QUESTION,
+	"??":       DOUBLEQUESTION,
+	"?.":       QUESTIONDOT,
+	"/":        DIVIDE,
+	"*":        MULTIPLY,
+	"%":        MODULO,
+	"**":       POWER,
+	"~/":       INTDIVIDE,
+	"&":        BITAND,
+	"|":        BITOR,
+	"^":        BITXOR,
+	"~":        BITNOT,
+	"<<":       LEFTSHIFT,
+	">>":       RIGHTSHIFT,
+	"+=":       PLUSEQUAL,
+	"-=":       MINUSEQUAL,
+	"*=":       MULTIPLYEQUAL,
+	"/=":       DIVIDEEQUAL,
+	"%=":       MODULOEQUAL,
+	"++":       INCREMENT,
+	"--":       DECREMENT,
+	"!":        BANG,
+	"=":        EQUAL,
+	">":        GREATER,
+	"<":        LESS,
+	"!=":       BANGEQUAL,
+	"==":       DOUBLEEQUAL,
+	">=":       GREATEREQUAL,
+	"<=":       LESSEQUAL,
+	"=>":       ARROW,
+	"and":      AND,
+	"or":       OR,
+	"break":    BREAK,
+	"catch":    CATCH,
+	"class":    CLASS,
+	"continue": CONTINUE,
+	"else":     ELSE,
+	"false":    FALSE,
+	"finally":  FINALLY,
+	"fun":      FUN,
+	"for":      FOR,
+	"if":       IF,
+	"in":       IN,
+	"nil":      NIL,
+	"print":    PRINT,
+	"return":   RETURN,
+	"super":    SUPER,
+	"this":     THIS,
+	"throw":    THROW,
+	"true":     TRUE,
+	"try":      TRY,
+	"var":      VAR,
+	"while":    WHILE,
+}
+
+// Keywords returns reserved words of the language in sorted order
+func Keywords() []string {
+	var keywords []string
+	for k := range _tokenMap {
+		if unicode.IsLetter(rune(k[0])) {
+			keywords = append(keywords, k)
+		}
+	}
+	sort.Strings(keywords)
+	return keywords
 }